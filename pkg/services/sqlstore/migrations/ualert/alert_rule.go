@@ -6,9 +6,11 @@ import (
 	"time"
 
 	"github.com/grafana/grafana/pkg/components/simplejson"
+	"github.com/grafana/grafana/pkg/expr"
 	"github.com/grafana/grafana/pkg/infra/log"
 	legacymodels "github.com/grafana/grafana/pkg/services/alerting/models"
 	ngmodels "github.com/grafana/grafana/pkg/services/ngalert/models"
+	"github.com/grafana/grafana/pkg/setting"
 	"github.com/grafana/grafana/pkg/tsdb/graphite"
 )
 
@@ -117,13 +119,20 @@ func (m *migration) makeAlertRule(l log.Logger, cond condition, da dashAlert, fo
 		return nil, fmt.Errorf("failed to migrate alert rule queries: %w", err)
 	}
 
-	uid, err := m.seenUIDs.generateUid()
+	// The uid is derived from the dashboard panel the alert belongs to, so that migrating the same data always
+	// results in the same rule uids.
+	uid, err := m.seenUIDs.generateDeterministicUid(fmt.Sprintf("%d-%s-%d", da.OrgId, da.DashboardUID, da.PanelId))
 	if err != nil {
 		return nil, fmt.Errorf("failed to migrate alert rule: %w", err)
 	}
 
 	name := normalizeRuleName(da.Name, uid)
 
+	baseInterval, minInterval := m.schedulerIntervals()
+	if m.mg.Cfg.UnifiedAlerting.UpgradeFailBelowMinInterval && time.Duration(da.Frequency)*time.Second < minInterval {
+		return nil, fmt.Errorf("%w: frequency %s, min_interval %s", ErrIntervalBelowMinimum, time.Duration(da.Frequency)*time.Second, minInterval)
+	}
+
 	isPaused := false
 	if da.State == "paused" {
 		isPaused = true
@@ -135,7 +144,7 @@ func (m *migration) makeAlertRule(l log.Logger, cond condition, da dashAlert, fo
 		UID:             uid,
 		Condition:       cond.Condition,
 		Data:            data,
-		IntervalSeconds: ruleAdjustInterval(da.Frequency),
+		IntervalSeconds: ruleAdjustInterval(l, da.Frequency, baseInterval, minInterval),
 		Version:         1,
 		NamespaceUID:    folderUID, // Folder already created, comes from env var.
 		RuleGroup:       name,
@@ -169,7 +178,7 @@ func migrateAlertRuleQueries(l log.Logger, data []alertQuery) ([]alertQuery, err
 	result := make([]alertQuery, 0, len(data))
 	for _, d := range data {
 		// queries that are expression are not relevant, skip them.
-		if d.DatasourceUID == expressionDatasourceUID {
+		if expr.IsDataSource(d.DatasourceUID) {
 			result = append(result, d)
 			continue
 		}
@@ -323,13 +332,45 @@ func (d *duration) UnmarshalJSON(b []byte) error {
 	}
 }
 
-func ruleAdjustInterval(freq int64) int64 {
-	// 10 corresponds to the SchedulerCfg, but TODO not worrying about fetching for now.
-	var baseFreq int64 = 10
-	if freq <= baseFreq {
-		return 10
+// schedulerIntervals returns the base interval of the scheduler and the minimum evaluation interval of alert rules.
+// The defaults of the scheduler are used if unified alerting settings have not been loaded.
+func (m *migration) schedulerIntervals() (time.Duration, time.Duration) {
+	baseInterval := setting.SchedulerBaseInterval
+	if m.mg.Cfg.UnifiedAlerting.BaseInterval > 0 {
+		baseInterval = m.mg.Cfg.UnifiedAlerting.BaseInterval
+	}
+	minInterval := baseInterval
+	if m.mg.Cfg.UnifiedAlerting.MinInterval > minInterval {
+		minInterval = m.mg.Cfg.UnifiedAlerting.MinInterval
 	}
-	return freq - (freq % baseFreq)
+	return baseInterval, minInterval
+}
+
+// ruleAdjustInterval converts the legacy alert frequency into an evaluation interval that is a multiple of the
+// scheduler base interval. Frequencies lower than the minimum evaluation interval are clamped to it, unless the
+// migration is configured to fail for them instead, see makeAlertRule.
+// Rule intervals are stored in whole seconds, so with a sub-second or fractional base interval the result is a
+// multiple of both the base interval and one second.
+func ruleAdjustInterval(l log.Logger, freq int64, baseInterval, minInterval time.Duration) int64 {
+	step := baseInterval / gcdDuration(baseInterval, time.Second) * time.Second
+	interval := time.Duration(freq) * time.Second
+	minFreq := minInterval
+	if r := minFreq % step; r != 0 {
+		minFreq += step - r
+	}
+	if interval < minFreq {
+		l.Warn("Alert frequency is lower than the minimum evaluation interval, using the minimum interval instead", "frequency", interval, "min_interval", minInterval)
+		return int64(minFreq / time.Second)
+	}
+	return int64((interval - interval%step) / time.Second)
+}
+
+// gcdDuration returns the greatest common divisor of two positive durations.
+func gcdDuration(a, b time.Duration) time.Duration {
+	for b != 0 {
+		a, b = b, a%b
+	}
+	return a
 }
 
 func transNoData(l log.Logger, s string) string {
@@ -341,7 +382,9 @@ func transNoData(l log.Logger, s string) string {
 	case legacymodels.NoDataSetAlerting:
 		return string(ngmodels.Alerting)
 	case legacymodels.NoDataKeepState:
-		return string(ngmodels.NoData) // "keep last state" translates to no data because we now emit a special alert when the state is "noData". The result is that the evaluation will not return firing and instead we'll raise the special alert.
+		// "keep last state" translates to no data because we now emit a special alert when the state is "noData". The result is that the evaluation will not return firing and instead we'll raise the special alert.
+		l.Warn("Keeping the last state on NoData is not supported, the alert will resolve on NoData and the DatasourceNoData alert is silenced instead", "old", s, "new", ngmodels.NoData)
+		return string(ngmodels.NoData)
 	default:
 		l.Warn("Unable to translate execution of NoData state. Using default execution", "old", s, "new", ngmodels.NoData)
 		return string(ngmodels.NoData)
@@ -355,6 +398,7 @@ func transExecErr(l log.Logger, s string) string {
 	case legacymodels.ExecutionErrorKeepState:
 		// Keep last state is translated to error as we now emit a
 		// DatasourceError alert when the state is error
+		l.Warn("Keeping the last state on execution error is not supported, the alert will resolve on error and the DatasourceError alert is silenced instead", "old", s, "new", ngmodels.ErrorErrState)
 		return string(ngmodels.ErrorErrState)
 	case legacymodels.ExecutionErrorSetOk:
 		return string(ngmodels.OkErrState)
@@ -4,22 +4,37 @@ import (
 	"encoding/json"
 	"strings"
 	"testing"
+	"time"
 
 	"github.com/google/uuid"
 	"github.com/stretchr/testify/require"
 
 	"github.com/grafana/grafana/pkg/components/simplejson"
+	"github.com/grafana/grafana/pkg/expr"
 	"github.com/grafana/grafana/pkg/infra/log/logtest"
 	"github.com/grafana/grafana/pkg/services/ngalert/models"
 )
 
 func TestMigrateAlertRuleQueries(t *testing.T) {
 	tc := []struct {
-		name     string
-		input    *simplejson.Json
-		expected string
-		err      error
+		name          string
+		datasourceUID string
+		input         *simplejson.Json
+		expected      string
+		err           error
 	}{
+		{
+			name:          "when query is an expression, it no-ops",
+			datasourceUID: expr.DatasourceUID,
+			input:         simplejson.NewFromAny(map[string]any{"hide": true}),
+			expected:      `{"hide":true}`,
+		},
+		{
+			name:          "when query is an expression with the old datasource UID, it no-ops",
+			datasourceUID: expr.OldDatasourceUID,
+			input:         simplejson.NewFromAny(map[string]any{"hide": true}),
+			expected:      `{"hide":true}`,
+		},
 		{
 			name:     "when a query has a sub query - it is extracted",
 			input:    simplejson.NewFromAny(map[string]any{"targetFull": "thisisafullquery", "target": "ahalfquery"}),
@@ -73,7 +88,7 @@ func TestMigrateAlertRuleQueries(t *testing.T) {
 		t.Run(tt.name, func(t *testing.T) {
 			model, err := tt.input.Encode()
 			require.NoError(t, err)
-			queries, err := migrateAlertRuleQueries(&logtest.Fake{}, []alertQuery{{Model: model}})
+			queries, err := migrateAlertRuleQueries(&logtest.Fake{}, []alertQuery{{DatasourceUID: tt.datasourceUID, Model: model}})
 			if tt.err != nil {
 				require.Error(t, err)
 				require.EqualError(t, err, tt.err.Error())
@@ -153,6 +168,19 @@ func TestMakeAlertRule(t *testing.T) {
 		})
 	})
 
+	t.Run("uid is the same when migrating the same dashboard panel", func(t *testing.T) {
+		da := createTestDashAlert()
+		da.DashboardUID = "dashboard"
+		da.PanelId = 2
+		cnd := createTestDashAlertCondition()
+
+		ar1, err := newTestMigration(t).makeAlertRule(&logtest.Fake{}, cnd, da, "folder")
+		require.NoError(t, err)
+		ar2, err := newTestMigration(t).makeAlertRule(&logtest.Fake{}, cnd, da, "folder")
+		require.NoError(t, err)
+		require.Equal(t, ar1.UID, ar2.UID)
+	})
+
 	t.Run("alert is not paused", func(t *testing.T) {
 		m := newTestMigration(t)
 		da := createTestDashAlert()
@@ -196,6 +224,33 @@ func TestMakeAlertRule(t *testing.T) {
 		require.Equal(t, string(models.ErrorErrState), ar.ExecErrState)
 	})
 
+	t.Run("frequency below the minimum interval is clamped", func(t *testing.T) {
+		m := newTestMigration(t)
+		da := createTestDashAlert()
+		da.Frequency = 5
+		cnd := createTestDashAlertCondition()
+
+		ar, err := m.makeAlertRule(&logtest.Fake{}, cnd, da, "folder")
+		require.NoError(t, err)
+		require.Equal(t, int64(10), ar.IntervalSeconds)
+	})
+
+	t.Run("frequency below the minimum interval fails if configured", func(t *testing.T) {
+		m := newTestMigration(t)
+		m.mg.Cfg.UnifiedAlerting.UpgradeFailBelowMinInterval = true
+		da := createTestDashAlert()
+		da.Frequency = 5
+		cnd := createTestDashAlertCondition()
+
+		_, err := m.makeAlertRule(&logtest.Fake{}, cnd, da, "folder")
+		require.ErrorIs(t, err, ErrIntervalBelowMinimum)
+
+		da.Frequency = 10
+		ar, err := m.makeAlertRule(&logtest.Fake{}, cnd, da, "folder")
+		require.NoError(t, err)
+		require.Equal(t, int64(10), ar.IntervalSeconds)
+	})
+
 	t.Run("migrate message template", func(t *testing.T) {
 		m := newTestMigration(t)
 		da := createTestDashAlert()
@@ -224,3 +279,124 @@ func createTestDashAlertCondition() condition {
 		Condition: "A",
 	}
 }
+
+func TestRuleAdjustInterval(t *testing.T) {
+	tc := []struct {
+		name         string
+		freq         int64
+		baseInterval time.Duration
+		minInterval  time.Duration
+		expected     int64
+	}{
+		{
+			name:         "frequency is rounded down to a multiple of the base interval",
+			freq:         65,
+			baseInterval: 10 * time.Second,
+			minInterval:  10 * time.Second,
+			expected:     60,
+		},
+		{
+			name:         "frequency equal to the minimum interval is kept",
+			freq:         10,
+			baseInterval: 10 * time.Second,
+			minInterval:  10 * time.Second,
+			expected:     10,
+		},
+		{
+			name:         "frequency lower than the minimum interval is clamped",
+			freq:         5,
+			baseInterval: 10 * time.Second,
+			minInterval:  10 * time.Second,
+			expected:     10,
+		},
+		{
+			name:         "frequency lower than a configured minimum interval is clamped",
+			freq:         50,
+			baseInterval: 10 * time.Second,
+			minInterval:  time.Minute,
+			expected:     60,
+		},
+		{
+			name:         "frequency is rounded down to a configured base interval",
+			freq:         95,
+			baseInterval: 30 * time.Second,
+			minInterval:  time.Minute,
+			expected:     90,
+		},
+		{
+			name:         "sub-second base interval does not round to zero",
+			freq:         15,
+			baseInterval: 500 * time.Millisecond,
+			minInterval:  500 * time.Millisecond,
+			expected:     15,
+		},
+		{
+			name:         "sub-second minimum interval is rounded up to a whole second",
+			freq:         0,
+			baseInterval: 500 * time.Millisecond,
+			minInterval:  500 * time.Millisecond,
+			expected:     1,
+		},
+		{
+			name:         "fractional base interval rounds down to a multiple of whole seconds",
+			freq:         10,
+			baseInterval: 1500 * time.Millisecond,
+			minInterval:  1500 * time.Millisecond,
+			expected:     9,
+		},
+		{
+			name:         "frequency lower than a fractional minimum interval is clamped to a whole second multiple",
+			freq:         1,
+			baseInterval: 1500 * time.Millisecond,
+			minInterval:  1500 * time.Millisecond,
+			expected:     3,
+		},
+	}
+
+	for _, tt := range tc {
+		t.Run(tt.name, func(t *testing.T) {
+			require.Equal(t, tt.expected, ruleAdjustInterval(&logtest.Fake{}, tt.freq, tt.baseInterval, tt.minInterval))
+		})
+	}
+}
+
+func TestTransNoDataAndExecErr(t *testing.T) {
+	tc := []struct {
+		name       string
+		noData     string
+		execErr    string
+		expNoData  models.NoDataState
+		expExecErr models.ExecutionErrorState
+		expWarns   int
+	}{
+		{
+			name:       "default states are translated without warnings",
+			expNoData:  models.NoData,
+			expExecErr: models.AlertingErrState,
+		},
+		{
+			name:       "explicit states are translated without warnings",
+			noData:     "alerting",
+			execErr:    "ok",
+			expNoData:  models.Alerting,
+			expExecErr: models.OkErrState,
+		},
+		{
+			name:       "keep last state warns about the approximation",
+			noData:     "keep_state",
+			execErr:    "keep_state",
+			expNoData:  models.NoData,
+			expExecErr: models.ErrorErrState,
+			expWarns:   2,
+		},
+	}
+
+	for _, tt := range tc {
+		t.Run(tt.name, func(t *testing.T) {
+			l := &logtest.Fake{}
+			require.Equal(t, string(tt.expNoData), transNoData(l, tt.noData))
+			require.Equal(t, string(tt.expExecErr), transExecErr(l, tt.execErr))
+			require.Equal(t, tt.expWarns, l.WarnLogs.Calls)
+		})
+	}
+}
@@ -14,6 +14,7 @@ import (
 	"github.com/prometheus/common/model"
 
 	"github.com/grafana/grafana/pkg/components/simplejson"
+	"github.com/grafana/grafana/pkg/infra/log"
 	ngModels "github.com/grafana/grafana/pkg/services/ngalert/models"
 )
 
@@ -22,7 +23,8 @@ const (
 	DisabledRepeatInterval = model.Duration(time.Duration(8736) * time.Hour) // 1y
 )
 
-type notificationChannel struct {
+// NotificationChannel is a legacy notification channel as stored in the alert_notification table.
+type NotificationChannel struct {
 	ID                    int64            `xorm:"id"`
 	OrgID                 int64            `xorm:"org_id"`
 	Uid                   string           `xorm:"uid"`
@@ -37,17 +39,17 @@ type notificationChannel struct {
 }
 
 // channelsPerOrg maps notification channels per organisation
-type channelsPerOrg map[int64][]*notificationChannel
+type channelsPerOrg map[int64][]*NotificationChannel
 
 // channelMap maps notification channels per organisation
-type defaultChannelsPerOrg map[int64][]*notificationChannel
+type defaultChannelsPerOrg map[int64][]*NotificationChannel
 
 // uidOrID for both uid and ID, primarily used for mapping legacy channel to migrated receiver.
 type uidOrID any
 
-// channelReceiver is a convenience struct that contains a notificationChannel and its corresponding migrated PostableApiReceiver.
+// channelReceiver is a convenience struct that contains a NotificationChannel and its corresponding migrated PostableApiReceiver.
 type channelReceiver struct {
-	channel  *notificationChannel
+	channel  *NotificationChannel
 	receiver *PostableApiReceiver
 }
 
@@ -163,7 +165,7 @@ func (m *migration) getNotificationChannelMap() (channelsPerOrg, defaultChannels
 	FROM
 		alert_notification
 	`
-	allChannels := []notificationChannel{}
+	allChannels := []NotificationChannel{}
 	err := m.sess.SQL(q).Find(&allChannels)
 	if err != nil {
 		return nil, nil, err
@@ -176,7 +178,8 @@ func (m *migration) getNotificationChannelMap() (channelsPerOrg, defaultChannels
 	allChannelsMap := make(channelsPerOrg)
 	defaultChannelsMap := make(defaultChannelsPerOrg)
 	for i, c := range allChannels {
-		if c.Type == "hipchat" || c.Type == "sensu" {
+		// Discontinued channel types can still be migrated by a registered ChannelMigrator.
+		if (c.Type == "hipchat" || c.Type == "sensu") && channelMigrators.get(c.Type) == nil {
 			m.mg.Logger.Error("Alert migration error: discontinued notification channel found", "type", c.Type, "name", c.Name, "uid", c.Uid)
 			continue
 		}
@@ -192,29 +195,53 @@ func (m *migration) getNotificationChannelMap() (channelsPerOrg, defaultChannels
 }
 
 // Create a notifier (PostableGrafanaReceiver) from a legacy notification channel
-func (m *migration) createNotifier(c *notificationChannel) (*PostableGrafanaReceiver, error) {
+func (m *migration) createNotifier(c *NotificationChannel) (*PostableGrafanaReceiver, error) {
 	uid, err := m.determineChannelUid(c)
 	if err != nil {
 		return nil, err
 	}
 
-	settings, secureSettings, err := migrateSettingsToSecureSettings(c.Type, c.Settings, c.SecureSettings)
+	l := m.mg.Logger.New("type", c.Type, "name", c.Name, "uid", c.Uid)
+	if cm := channelMigrators.get(c.Type); cm != nil {
+		notifier, err := cm.Migrate(c, decryptSecureSettings(l, c.SecureSettings))
+		if err != nil {
+			return nil, fmt.Errorf("failed to migrate notification channel %q of type %s: %w", c.Name, c.Type, err)
+		}
+		if notifier == nil {
+			return nil, fmt.Errorf("failed to migrate notification channel %q of type %s: no notifier returned", c.Name, c.Type)
+		}
+		if notifier.Type == "" {
+			return nil, fmt.Errorf("failed to migrate notification channel %q of type %s: notifier has no type", c.Name, c.Type)
+		}
+		if notifier.Settings == nil {
+			notifier.Settings = simplejson.New()
+		}
+		notifier.UID = uid
+		notifier.DisableResolveMessage = c.DisableResolveMessage
+		notifier.SecureSettings = encryptSecureSettings(notifier.SecureSettings)
+		return notifier, nil
+	}
+
+	settings, secureSettings, err := migrateSettingsToSecureSettings(l, c.Type, c.Settings, c.SecureSettings)
 	if err != nil {
 		return nil, err
 	}
 
-	return &PostableGrafanaReceiver{
+	notifier := &PostableGrafanaReceiver{
 		UID:                   uid,
 		Name:                  c.Name,
 		Type:                  c.Type,
 		DisableResolveMessage: c.DisableResolveMessage,
 		Settings:              settings,
 		SecureSettings:        secureSettings,
-	}, nil
+	}
+	transformNotifierSettings(l, notifier)
+
+	return notifier, nil
 }
 
 // Create one receiver for every unique notification channel.
-func (m *migration) createReceivers(allChannels []*notificationChannel) (map[uidOrID]*PostableApiReceiver, []channelReceiver, error) {
+func (m *migration) createReceivers(allChannels []*NotificationChannel) (map[uidOrID]*PostableApiReceiver, []channelReceiver, error) {
 	receivers := make([]channelReceiver, 0, len(allChannels))
 	receiversMap := make(map[uidOrID]*PostableApiReceiver)
 
@@ -259,13 +286,22 @@ func (m *migration) createReceivers(allChannels []*notificationChannel) (map[uid
 	return receiversMap, receivers, nil
 }
 
+// defaultGroupBy returns the labels to group by in the root-level route. Unless configured otherwise, this is the
+// folder and alert rule name to keep parity with pre-migration notifications.
+func (m *migration) defaultGroupBy() []string {
+	if len(m.mg.Cfg.UnifiedAlerting.UpgradeGroupBy) > 0 {
+		return m.mg.Cfg.UnifiedAlerting.UpgradeGroupBy
+	}
+	return []string{ngModels.FolderTitleLabel, model.AlertNameLabel}
+}
+
 // Create the root-level route with the default receiver. If no new receiver is created specifically for the root-level route, the returned receiver will be nil.
-func (m *migration) createDefaultRouteAndReceiver(defaultChannels []*notificationChannel) (*PostableApiReceiver, *Route, error) {
+func (m *migration) createDefaultRouteAndReceiver(defaultChannels []*NotificationChannel) (*PostableApiReceiver, *Route, error) {
 	defaultReceiverName := "autogen-contact-point-default"
 	defaultRoute := &Route{
 		Receiver:       defaultReceiverName,
 		Routes:         make([]*Route, 0),
-		GroupByStr:     []string{ngModels.FolderTitleLabel, model.AlertNameLabel}, // To keep parity with pre-migration notifications.
+		GroupByStr:     m.defaultGroupBy(),
 		RepeatInterval: nil,
 	}
 	newDefaultReceiver := &PostableApiReceiver{
@@ -378,7 +414,7 @@ func (m *migration) filterReceiversForAlert(name string, channelIDs []uidOrID, r
 	return filteredReceiverNames
 }
 
-func (m *migration) determineChannelUid(c *notificationChannel) (string, error) {
+func (m *migration) determineChannelUid(c *NotificationChannel) (string, error) {
 	legacyUid := c.Uid
 	if legacyUid == "" {
 		newUid, err := m.seenUIDs.generateUid()
@@ -404,7 +440,10 @@ func (m *migration) determineChannelUid(c *notificationChannel) (string, error)
 // Some settings were migrated from settings to secure settings in between.
 // See https://grafana.com/docs/grafana/latest/installation/upgrading/#ensure-encryption-of-existing-alert-notification-channel-secrets.
 // migrateSettingsToSecureSettings takes care of that.
-func migrateSettingsToSecureSettings(chanType string, settings *simplejson.Json, secureSettings SecureJsonData) (*simplejson.Json, map[string]string, error) {
+// Secure settings that cannot be decrypted are left out with a warning. The channel is still migrated if the unified
+// alerting notifier does not require them, such as the webhook password. Otherwise the migrated integration fails
+// validation, which fails the migration unless invalid channels are ignored and the integration is removed.
+func migrateSettingsToSecureSettings(l log.Logger, chanType string, settings *simplejson.Json, secureSettings SecureJsonData) (*simplejson.Json, map[string]string, error) {
 	keys := []string{}
 	switch chanType {
 	case "slack":
@@ -427,7 +466,7 @@ func migrateSettingsToSecureSettings(chanType string, settings *simplejson.Json,
 		keys = []string{"api_secret"}
 	}
 
-	newSecureSettings := secureSettings.Decrypt()
+	newSecureSettings := decryptSecureSettings(l, secureSettings)
 	cloneSettings := simplejson.New()
 	settingsMap, err := settings.Map()
 	if err != nil {
@@ -448,12 +487,27 @@ func migrateSettingsToSecureSettings(chanType string, settings *simplejson.Json,
 		}
 	}
 
-	encryptedData := GetEncryptedJsonData(newSecureSettings)
-	for k, v := range encryptedData {
-		newSecureSettings[k] = base64.StdEncoding.EncodeToString(v)
+	return cloneSettings, encryptSecureSettings(newSecureSettings), nil
+}
+
+// decryptSecureSettings decrypts the secure settings of a legacy channel. Values that cannot be decrypted are left out
+// with a warning.
+func decryptSecureSettings(l log.Logger, secureSettings SecureJsonData) map[string]string {
+	decrypted, failed := secureSettings.decryptValues()
+	if len(failed) > 0 {
+		l.Warn("Failed to decrypt secure settings of legacy channel, leaving them out of the migrated contact point", "settings", failed)
 	}
+	return decrypted
+}
 
-	return cloneSettings, newSecureSettings, nil
+// encryptSecureSettings encrypts the plain-text secure settings of a notifier and base64-encodes them, as expected in
+// the secure settings of a PostableGrafanaReceiver.
+func encryptSecureSettings(secureSettings map[string]string) map[string]string {
+	encrypted := make(map[string]string, len(secureSettings))
+	for k, v := range GetEncryptedJsonData(secureSettings) {
+		encrypted[k] = base64.StdEncoding.EncodeToString(v)
+	}
+	return encrypted
 }
 
 // Below is a snapshot of all the config and supporting functions imported
@@ -0,0 +1,50 @@
+package ualert
+
+import (
+	"sync"
+)
+
+// ChannelMigrator converts legacy notification channels into unified alerting notifiers.
+// Custom builds can register a ChannelMigrator with RegisterChannelMigrator to provide a conversion for channel types
+// that the built-in migration does not handle, or handles differently.
+type ChannelMigrator interface {
+	// CanHandle returns true if the migrator converts notification channels of the given type.
+	CanHandle(channelType string) bool
+	// Migrate converts the legacy notification channel into a notifier. The UID of the returned notifier is always
+	// overwritten by the migration to guarantee uniqueness, and DisableResolveMessage is copied from the channel.
+	// The returned notifier must have a Type. Settings can be nil if the notifier has no settings.
+	// secureSettings holds the decrypted secure settings of the channel, use them instead of channel.SecureSettings,
+	// which are still encrypted. Values that cannot be decrypted are left out and logged as a warning.
+	// SecureSettings of the returned notifier must contain plain-text values, the migration encrypts them with the
+	// secret key before the notifier is saved.
+	Migrate(channel *NotificationChannel, secureSettings map[string]string) (*PostableGrafanaReceiver, error)
+}
+
+// channelMigratorRegistry holds the registered channel migrators in registration order.
+type channelMigratorRegistry struct {
+	mtx       sync.RWMutex
+	migrators []ChannelMigrator
+}
+
+var channelMigrators = &channelMigratorRegistry{}
+
+// RegisterChannelMigrator adds a ChannelMigrator that is consulted before the built-in channel migration.
+// Migrators are consulted in registration order and the first one that can handle a channel type is used.
+// It must be called before the database migrations run.
+func RegisterChannelMigrator(cm ChannelMigrator) {
+	channelMigrators.mtx.Lock()
+	defer channelMigrators.mtx.Unlock()
+	channelMigrators.migrators = append(channelMigrators.migrators, cm)
+}
+
+// get returns the first registered ChannelMigrator that can handle the given channel type, or nil if there is none.
+func (r *channelMigratorRegistry) get(channelType string) ChannelMigrator {
+	r.mtx.RLock()
+	defer r.mtx.RUnlock()
+	for _, cm := range r.migrators {
+		if cm.CanHandle(channelType) {
+			return cm
+		}
+	}
+	return nil
+}
@@ -0,0 +1,118 @@
+package ualert
+
+import (
+	"fmt"
+
+	"github.com/grafana/grafana/pkg/infra/log"
+)
+
+// pagerdutyDefaultSeverity is the severity used by legacy PagerDuty channels if none is configured.
+const pagerdutyDefaultSeverity = "critical"
+
+// settingsTransformation adjusts a migrated notifier for legacy settings that have a different name or meaning in
+// unified alerting, or no equivalent at all.
+type settingsTransformation func(l log.Logger, n *PostableGrafanaReceiver)
+
+// settingsTransformations contains the settings transformations for each legacy notification channel type.
+// Settings of channel types without an entry are migrated verbatim.
+var settingsTransformations = map[string]settingsTransformation{
+	"pagerduty": transformPagerdutySettings,
+	"victorops": transformVictoropsSettings,
+	"slack":     transformSlackSettings,
+	"telegram":  transformTelegramSettings,
+}
+
+// transformNotifierSettings applies the settings transformation registered for the type of the notifier, if any.
+func transformNotifierSettings(l log.Logger, n *PostableGrafanaReceiver) {
+	if transform, ok := settingsTransformations[n.Type]; ok {
+		transform(l, n)
+	}
+}
+
+// transformPagerdutySettings maps autoResolve onto DisableResolveMessage. Legacy PagerDuty channels only sent
+// resolved events if autoResolve was enabled, which it was not by default.
+func transformPagerdutySettings(l log.Logger, n *PostableGrafanaReceiver) {
+	if !n.Settings.Get("autoResolve").MustBool(false) && !n.DisableResolveMessage {
+		l.Info("Legacy PagerDuty channel does not auto-resolve, disabling resolve message")
+		n.DisableResolveMessage = true
+	}
+	n.Settings.Del("autoResolve")
+	// Unified alerting templates the summary and details, so there is no equivalent setting.
+	dropSetting(l, n, "messageInDetails")
+
+	// Legacy PagerDuty channels let alert rule tags override the severity, class, component and group of the event.
+	// Alert rule tags are migrated to labels, so the same is achieved with templates that read those labels.
+	severity := n.Settings.Get("severity").MustString(pagerdutyDefaultSeverity)
+	n.Settings.Set("severity", pagerdutyLabelTemplate("severity", severity, pagerdutySeverities))
+	for key, fallback := range pagerdutyLabelDefaults {
+		if _, ok := n.Settings.CheckGet(key); !ok {
+			n.Settings.Set(key, pagerdutyLabelTemplate(key, fallback, ""))
+		}
+	}
+}
+
+// pagerdutySeverities matches the severities accepted by PagerDuty, ignoring case. Legacy PagerDuty channels ignored
+// severity tags with any other value.
+const pagerdutySeverities = "^(?i:info|warning|error|critical)$"
+
+// pagerdutyLabelDefaults contains the values of the event fields that legacy PagerDuty channels sent if no tag
+// overrode them. Component was always sent as Grafana, class and group use the defaults of unified alerting.
+var pagerdutyLabelDefaults = map[string]string{
+	"class":     "default",
+	"component": "Grafana",
+	"group":     "default",
+}
+
+// pagerdutyLabelTemplate returns a template that renders the value of the label with the given name, matched
+// ignoring case like legacy tags were, or the fallback if there is none. If valuePattern is set, label values that
+// do not match it are ignored and matching values are lowercased.
+func pagerdutyLabelTemplate(name, fallback, valuePattern string) string {
+	cond := fmt.Sprintf(`eq (toLower $k) %q`, name)
+	value := "$v"
+	if valuePattern != "" {
+		cond = fmt.Sprintf(`and (%s) (match %q $v)`, cond, valuePattern)
+		value = "toLower $v"
+	}
+	return fmt.Sprintf(`{{ $value := %q }}{{ range $k, $v := .CommonLabels }}{{ if %s }}{{ $value = %s }}{{ end }}{{ end }}{{ $value }}`, fallback, cond, value)
+}
+
+// transformVictoropsSettings maps autoResolve onto DisableResolveMessage. Legacy VictorOps channels sent
+// recovery messages unless autoResolve was disabled.
+func transformVictoropsSettings(l log.Logger, n *PostableGrafanaReceiver) {
+	if !n.Settings.Get("autoResolve").MustBool(true) && !n.DisableResolveMessage {
+		l.Info("Legacy VictorOps channel does not auto-resolve, disabling resolve message")
+		n.DisableResolveMessage = true
+	}
+	n.Settings.Del("autoResolve")
+	// Unified alerting does not send a separate message type for NoData.
+	dropSetting(l, n, "noDataAlertType")
+}
+
+// transformSlackSettings removes settings of legacy Slack channels that have no equivalent in unified alerting.
+// Mentions are parsed the same way in both, but an invalid mentionChannel, which legacy alerting only rejected when
+// the channel was saved, would make the whole Alertmanager configuration invalid.
+func transformSlackSettings(l log.Logger, n *PostableGrafanaReceiver) {
+	// The unified alerting Slack notifier has no uploadImage setting. It uploads images whenever a bot token is used.
+	dropSetting(l, n, "uploadImage")
+
+	switch mentionChannel := n.Settings.Get("mentionChannel").MustString(); mentionChannel {
+	case "", "here", "channel":
+	default:
+		l.Warn("Legacy Slack channel has an invalid mentionChannel, channel will not be mentioned", "mentionChannel", mentionChannel)
+		n.Settings.Del("mentionChannel")
+	}
+}
+
+// transformTelegramSettings removes settings of legacy Telegram channels that have no equivalent in unified alerting.
+func transformTelegramSettings(l log.Logger, n *PostableGrafanaReceiver) {
+	dropSetting(l, n, "uploadImage")
+}
+
+// dropSetting removes a setting that has no equivalent in unified alerting from the notifier.
+func dropSetting(l log.Logger, n *PostableGrafanaReceiver, key string) {
+	if _, ok := n.Settings.CheckGet(key); !ok {
+		return
+	}
+	l.Debug("Dropping legacy setting that is not supported in unified alerting", "setting", key)
+	n.Settings.Del(key)
+}
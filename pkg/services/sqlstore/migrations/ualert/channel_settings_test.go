@@ -0,0 +1,205 @@
+package ualert
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/prometheus/alertmanager/template"
+	"github.com/stretchr/testify/require"
+
+	"github.com/grafana/grafana/pkg/components/simplejson"
+	"github.com/grafana/grafana/pkg/infra/log/logtest"
+)
+
+func TestTransformNotifierSettings(t *testing.T) {
+	pagerdutySettings := func(severity, class string) string {
+		settings, err := json.Marshal(map[string]string{
+			"severity":  pagerdutyLabelTemplate("severity", severity, pagerdutySeverities),
+			"class":     class,
+			"component": pagerdutyLabelTemplate("component", "Grafana", ""),
+			"group":     pagerdutyLabelTemplate("group", "default", ""),
+		})
+		require.NoError(t, err)
+		return string(settings)
+	}
+
+	tc := []struct {
+		name                     string
+		channelType              string
+		settings                 map[string]any
+		disableResolveMessage    bool
+		expSettings              string
+		expDisableResolveMessage bool
+	}{
+		{
+			name:                     "pagerduty without autoResolve disables resolve message",
+			channelType:              "pagerduty",
+			settings:                 map[string]any{"severity": "error"},
+			expSettings:              pagerdutySettings("error", pagerdutyLabelTemplate("class", "default", "")),
+			expDisableResolveMessage: true,
+		},
+		{
+			name:                     "pagerduty with autoResolve keeps resolve message",
+			channelType:              "pagerduty",
+			settings:                 map[string]any{"autoResolve": true, "messageInDetails": true, "class": "cls"},
+			expSettings:              pagerdutySettings("critical", "cls"),
+			expDisableResolveMessage: false,
+		},
+		{
+			name:                     "victorops without autoResolve keeps resolve message",
+			channelType:              "victorops",
+			settings:                 map[string]any{"url": "http://localhost", "noDataAlertType": "WARNING"},
+			expSettings:              `{"url":"http://localhost"}`,
+			expDisableResolveMessage: false,
+		},
+		{
+			name:                     "victorops with autoResolve disabled disables resolve message",
+			channelType:              "victorops",
+			settings:                 map[string]any{"url": "http://localhost", "autoResolve": false},
+			expSettings:              `{"url":"http://localhost"}`,
+			expDisableResolveMessage: true,
+		},
+		{
+			name:                     "disabled resolve message is kept",
+			channelType:              "victorops",
+			settings:                 map[string]any{"autoResolve": true},
+			disableResolveMessage:    true,
+			expSettings:              `{}`,
+			expDisableResolveMessage: true,
+		},
+		{
+			name:        "slack drops uploadImage",
+			channelType: "slack",
+			settings:    map[string]any{"recipient": "#alerts", "uploadImage": true},
+			expSettings: `{"recipient":"#alerts"}`,
+		},
+		{
+			name:        "slack keeps mentions",
+			channelType: "slack",
+			settings:    map[string]any{"mentionChannel": "here", "mentionUsers": "U1, U2", "mentionGroups": "G1"},
+			expSettings: `{"mentionChannel":"here","mentionUsers":"U1, U2","mentionGroups":"G1"}`,
+		},
+		{
+			name:        "slack drops invalid mentionChannel",
+			channelType: "slack",
+			settings:    map[string]any{"mentionChannel": "everyone", "mentionUsers": "U1"},
+			expSettings: `{"mentionUsers":"U1"}`,
+		},
+		{
+			name:        "telegram drops uploadImage",
+			channelType: "telegram",
+			settings:    map[string]any{"chatid": "123", "uploadImage": false},
+			expSettings: `{"chatid":"123"}`,
+		},
+		{
+			name:        "other types are not changed",
+			channelType: "email",
+			settings:    map[string]any{"addresses": "test@example.com", "uploadImage": true},
+			expSettings: `{"addresses":"test@example.com","uploadImage":true}`,
+		},
+	}
+
+	for _, tt := range tc {
+		t.Run(tt.name, func(t *testing.T) {
+			n := &PostableGrafanaReceiver{
+				Type:                  tt.channelType,
+				DisableResolveMessage: tt.disableResolveMessage,
+				Settings:              simplejson.NewFromAny(tt.settings),
+			}
+			transformNotifierSettings(&logtest.Fake{}, n)
+
+			settings, err := n.Settings.MarshalJSON()
+			require.NoError(t, err)
+			require.JSONEq(t, tt.expSettings, string(settings))
+			require.Equal(t, tt.expDisableResolveMessage, n.DisableResolveMessage)
+		})
+	}
+}
+
+func TestPagerdutyLabelTemplate(t *testing.T) {
+	tmpl, err := template.FromGlobs(nil)
+	require.NoError(t, err)
+
+	tc := []struct {
+		name     string
+		label    string
+		fallback string
+		pattern  string
+		labels   template.KV
+		expected string
+	}{
+		{
+			name:     "component defaults to Grafana",
+			label:    "component",
+			fallback: "Grafana",
+			labels:   template.KV{"team": "a"},
+			expected: "Grafana",
+		},
+		{
+			name:     "class defaults to the unified alerting default",
+			label:    "class",
+			fallback: "default",
+			expected: "default",
+		},
+		{
+			name:     "group defaults to the unified alerting default",
+			label:    "group",
+			fallback: "default",
+			expected: "default",
+		},
+		{
+			name:     "component label overrides the default",
+			label:    "component",
+			fallback: "Grafana",
+			labels:   template.KV{"component": "db"},
+			expected: "db",
+		},
+		{
+			name:     "label name is matched ignoring case",
+			label:    "group",
+			fallback: "default",
+			labels:   template.KV{"Group": "backend"},
+			expected: "backend",
+		},
+		{
+			name:     "severity without label uses the configured severity",
+			label:    "severity",
+			fallback: "warning",
+			pattern:  pagerdutySeverities,
+			expected: "warning",
+		},
+		{
+			name:     "severity label overrides the configured severity",
+			label:    "severity",
+			fallback: "critical",
+			pattern:  pagerdutySeverities,
+			labels:   template.KV{"severity": "info"},
+			expected: "info",
+		},
+		{
+			name:     "severity label is lowercased",
+			label:    "severity",
+			fallback: "critical",
+			pattern:  pagerdutySeverities,
+			labels:   template.KV{"Severity": "ERROR"},
+			expected: "error",
+		},
+		{
+			name:     "unsupported severity label is ignored",
+			label:    "severity",
+			fallback: "critical",
+			pattern:  pagerdutySeverities,
+			labels:   template.KV{"severity": "high"},
+			expected: "critical",
+		},
+	}
+
+	for _, tt := range tc {
+		t.Run(tt.name, func(t *testing.T) {
+			text := pagerdutyLabelTemplate(tt.label, tt.fallback, tt.pattern)
+			res, err := tmpl.ExecuteTextString(text, template.Data{CommonLabels: tt.labels})
+			require.NoError(t, err)
+			require.Equal(t, tt.expected, res)
+		})
+	}
+}
@@ -1,6 +1,8 @@
 package ualert
 
 import (
+	"encoding/base64"
+	"fmt"
 	"testing"
 	"time"
 
@@ -11,7 +13,10 @@ import (
 	"github.com/stretchr/testify/require"
 
 	"github.com/grafana/grafana/pkg/components/simplejson"
+	"github.com/grafana/grafana/pkg/infra/log/logtest"
 	ngModels "github.com/grafana/grafana/pkg/services/ngalert/models"
+	"github.com/grafana/grafana/pkg/setting"
+	"github.com/grafana/grafana/pkg/util"
 )
 
 func TestFilterReceiversForAlert(t *testing.T) {
@@ -122,13 +127,13 @@ func TestFilterReceiversForAlert(t *testing.T) {
 func TestCreateRoute(t *testing.T) {
 	tc := []struct {
 		name     string
-		channel  *notificationChannel
+		channel  *NotificationChannel
 		recv     *PostableApiReceiver
 		expected *Route
 	}{
 		{
 			name:    "when a receiver is passed in, the route should regex match based on quoted name with continue=true",
-			channel: &notificationChannel{},
+			channel: &NotificationChannel{},
 			recv: &PostableApiReceiver{
 				Name: "recv1",
 			},
@@ -143,7 +148,7 @@ func TestCreateRoute(t *testing.T) {
 		},
 		{
 			name:    "notification channel should be escaped for regex in the matcher",
-			channel: &notificationChannel{},
+			channel: &NotificationChannel{},
 			recv: &PostableApiReceiver{
 				Name: `. ^ $ * + - ? ( ) [ ] { } \ |`,
 			},
@@ -158,7 +163,7 @@ func TestCreateRoute(t *testing.T) {
 		},
 		{
 			name:    "when a channel has sendReminder=true, the route should use the frequency in repeat interval",
-			channel: &notificationChannel{SendReminder: true, Frequency: model.Duration(time.Duration(42) * time.Hour)},
+			channel: &NotificationChannel{SendReminder: true, Frequency: model.Duration(time.Duration(42) * time.Hour)},
 			recv: &PostableApiReceiver{
 				Name: "recv1",
 			},
@@ -173,7 +178,7 @@ func TestCreateRoute(t *testing.T) {
 		},
 		{
 			name:    "when a channel has sendReminder=false, the route should ignore the frequency in repeat interval and use DisabledRepeatInterval",
-			channel: &notificationChannel{SendReminder: false, Frequency: model.Duration(time.Duration(42) * time.Hour)},
+			channel: &NotificationChannel{SendReminder: false, Frequency: model.Duration(time.Duration(42) * time.Hour)},
 			recv: &PostableApiReceiver{
 				Name: "recv1",
 			},
@@ -214,28 +219,28 @@ func TestCreateRoute(t *testing.T) {
 	}
 }
 
-func createNotChannel(t *testing.T, uid string, id int64, name string) *notificationChannel {
+func createNotChannel(t *testing.T, uid string, id int64, name string) *NotificationChannel {
 	t.Helper()
-	return &notificationChannel{Uid: uid, ID: id, Name: name, Settings: simplejson.New()}
+	return &NotificationChannel{Uid: uid, ID: id, Name: name, Settings: simplejson.New()}
 }
 
-func createNotChannelWithReminder(t *testing.T, uid string, id int64, name string, frequency model.Duration) *notificationChannel {
+func createNotChannelWithReminder(t *testing.T, uid string, id int64, name string, frequency model.Duration) *NotificationChannel {
 	t.Helper()
-	return &notificationChannel{Uid: uid, ID: id, Name: name, SendReminder: true, Frequency: frequency, Settings: simplejson.New()}
+	return &NotificationChannel{Uid: uid, ID: id, Name: name, SendReminder: true, Frequency: frequency, Settings: simplejson.New()}
 }
 
 func TestCreateReceivers(t *testing.T) {
 	tc := []struct {
 		name            string
-		allChannels     []*notificationChannel
-		defaultChannels []*notificationChannel
+		allChannels     []*NotificationChannel
+		defaultChannels []*NotificationChannel
 		expRecvMap      map[uidOrID]*PostableApiReceiver
 		expRecv         []channelReceiver
 		expErr          error
 	}{
 		{
 			name:        "when given notification channels migrate them to receivers",
-			allChannels: []*notificationChannel{createNotChannel(t, "uid1", int64(1), "name1"), createNotChannel(t, "uid2", int64(2), "name2")},
+			allChannels: []*NotificationChannel{createNotChannel(t, "uid1", int64(1), "name1"), createNotChannel(t, "uid2", int64(2), "name2")},
 			expRecvMap: map[uidOrID]*PostableApiReceiver{
 				"uid1": {
 					Name:                    "name1",
@@ -273,7 +278,7 @@ func TestCreateReceivers(t *testing.T) {
 		},
 		{
 			name:        "when given notification channel contains double quote sanitize with underscore",
-			allChannels: []*notificationChannel{createNotChannel(t, "uid1", int64(1), "name\"1")},
+			allChannels: []*NotificationChannel{createNotChannel(t, "uid1", int64(1), "name\"1")},
 			expRecvMap: map[uidOrID]*PostableApiReceiver{
 				"uid1": {
 					Name:                    "name_1",
@@ -296,7 +301,7 @@ func TestCreateReceivers(t *testing.T) {
 		},
 		{
 			name:        "when given notification channels collide after sanitization add short hash to end",
-			allChannels: []*notificationChannel{createNotChannel(t, "uid1", int64(1), "name\"1"), createNotChannel(t, "uid2", int64(2), "name_1")},
+			allChannels: []*NotificationChannel{createNotChannel(t, "uid1", int64(1), "name\"1"), createNotChannel(t, "uid2", int64(2), "name_1")},
 			expRecvMap: map[uidOrID]*PostableApiReceiver{
 				"uid1": {
 					Name:                    "name_1",
@@ -365,14 +370,15 @@ func TestCreateDefaultRouteAndReceiver(t *testing.T) {
 	tc := []struct {
 		name            string
 		amConfig        *PostableUserConfig
-		defaultChannels []*notificationChannel
+		groupBy         []string
+		defaultChannels []*NotificationChannel
 		expRecv         *PostableApiReceiver
 		expRoute        *Route
 		expErr          error
 	}{
 		{
 			name:            "when given multiple default notification channels migrate them to a single receiver",
-			defaultChannels: []*notificationChannel{createNotChannel(t, "uid1", int64(1), "name1"), createNotChannel(t, "uid2", int64(2), "name2")},
+			defaultChannels: []*NotificationChannel{createNotChannel(t, "uid1", int64(1), "name1"), createNotChannel(t, "uid2", int64(2), "name2")},
 			expRecv: &PostableApiReceiver{
 				Name:                    "autogen-contact-point-default",
 				GrafanaManagedReceivers: []*PostableGrafanaReceiver{{Name: "name1"}, {Name: "name2"}},
@@ -386,7 +392,7 @@ func TestCreateDefaultRouteAndReceiver(t *testing.T) {
 		},
 		{
 			name: "when given multiple default notification channels migrate them to a single receiver with RepeatInterval set to be the minimum of all channel frequencies",
-			defaultChannels: []*notificationChannel{
+			defaultChannels: []*NotificationChannel{
 				createNotChannelWithReminder(t, "uid1", int64(1), "name1", model.Duration(42)),
 				createNotChannelWithReminder(t, "uid2", int64(2), "name2", model.Duration(100000)),
 			},
@@ -403,7 +409,7 @@ func TestCreateDefaultRouteAndReceiver(t *testing.T) {
 		},
 		{
 			name:            "when given no default notification channels create a single empty receiver for default",
-			defaultChannels: []*notificationChannel{},
+			defaultChannels: []*NotificationChannel{},
 			expRecv: &PostableApiReceiver{
 				Name:                    "autogen-contact-point-default",
 				GrafanaManagedReceivers: []*PostableGrafanaReceiver{},
@@ -417,7 +423,7 @@ func TestCreateDefaultRouteAndReceiver(t *testing.T) {
 		},
 		{
 			name:            "when given a single default notification channels don't create a new default receiver",
-			defaultChannels: []*notificationChannel{createNotChannel(t, "uid1", int64(1), "name1")},
+			defaultChannels: []*NotificationChannel{createNotChannel(t, "uid1", int64(1), "name1")},
 			expRecv:         nil,
 			expRoute: &Route{
 				Receiver:       "name1",
@@ -428,7 +434,7 @@ func TestCreateDefaultRouteAndReceiver(t *testing.T) {
 		},
 		{
 			name:            "when given a single default notification channel with SendReminder=true, use the channels Frequency as the RepeatInterval",
-			defaultChannels: []*notificationChannel{createNotChannelWithReminder(t, "uid1", int64(1), "name1", model.Duration(42))},
+			defaultChannels: []*NotificationChannel{createNotChannelWithReminder(t, "uid1", int64(1), "name1", model.Duration(42))},
 			expRecv:         nil,
 			expRoute: &Route{
 				Receiver:       "name1",
@@ -437,11 +443,24 @@ func TestCreateDefaultRouteAndReceiver(t *testing.T) {
 				RepeatInterval: durationPointer(model.Duration(42)),
 			},
 		},
+		{
+			name:            "when group by is configured, use it for the root-level route",
+			groupBy:         []string{"..."},
+			defaultChannels: []*NotificationChannel{createNotChannel(t, "uid1", int64(1), "name1")},
+			expRecv:         nil,
+			expRoute: &Route{
+				Receiver:       "name1",
+				Routes:         make([]*Route, 0),
+				GroupByStr:     []string{"..."},
+				RepeatInterval: durationPointer(DisabledRepeatInterval),
+			},
+		},
 	}
 
 	for _, tt := range tc {
 		t.Run(tt.name, func(t *testing.T) {
 			m := newTestMigration(t)
+			m.mg.Cfg.UnifiedAlerting.UpgradeGroupBy = tt.groupBy
 			recv, route, err := m.createDefaultRouteAndReceiver(tt.defaultChannels)
 			if tt.expErr != nil {
 				require.Error(t, err)
@@ -469,3 +488,209 @@ func TestCreateDefaultRouteAndReceiver(t *testing.T) {
 func durationPointer(d model.Duration) *model.Duration {
 	return &d
 }
+
+type fakeChannelMigrator struct {
+	channelType string
+}
+
+func (f fakeChannelMigrator) CanHandle(channelType string) bool {
+	return channelType == f.channelType
+}
+
+func (f fakeChannelMigrator) Migrate(c *NotificationChannel, secureSettings map[string]string) (*PostableGrafanaReceiver, error) {
+	if c.Settings.Get("fail").MustBool() {
+		return nil, fmt.Errorf("failed")
+	}
+	if c.Settings.Get("empty").MustBool() {
+		return nil, nil
+	}
+	if c.Settings.Get("untyped").MustBool() {
+		return &PostableGrafanaReceiver{Name: c.Name}, nil
+	}
+	if c.Settings.Get("noSettings").MustBool() {
+		return &PostableGrafanaReceiver{Name: c.Name, Type: "email"}, nil
+	}
+	return &PostableGrafanaReceiver{
+		UID:            "ignored",
+		Name:           c.Name,
+		Type:           "webhook",
+		Settings:       simplejson.NewFromAny(map[string]any{"url": c.Settings.Get("endpoint").MustString()}),
+		SecureSettings: secureSettings,
+	}, nil
+}
+
+func TestCreateNotifierWithChannelMigrator(t *testing.T) {
+	t.Cleanup(func() {
+		channelMigrators = &channelMigratorRegistry{}
+	})
+	RegisterChannelMigrator(fakeChannelMigrator{channelType: "custom"})
+
+	t.Run("registered migrator is used for handled channel types", func(t *testing.T) {
+		m := newTestMigration(t)
+		c := &NotificationChannel{Uid: "uid1", Name: "name1", Type: "custom", Settings: simplejson.NewFromAny(map[string]any{"endpoint": "http://localhost"})}
+
+		notifier, err := m.createNotifier(c)
+		require.NoError(t, err)
+		require.Equal(t, "uid1", notifier.UID)
+		require.Equal(t, "webhook", notifier.Type)
+		require.Equal(t, "http://localhost", notifier.Settings.Get("url").MustString())
+	})
+
+	t.Run("errors from registered migrator are returned", func(t *testing.T) {
+		m := newTestMigration(t)
+		c := &NotificationChannel{Uid: "uid1", Name: "name1", Type: "custom", Settings: simplejson.NewFromAny(map[string]any{"fail": true})}
+
+		_, err := m.createNotifier(c)
+		require.ErrorContains(t, err, "failed to migrate notification channel \"name1\" of type custom")
+	})
+
+	t.Run("missing notifier from registered migrator is an error", func(t *testing.T) {
+		m := newTestMigration(t)
+		c := &NotificationChannel{Uid: "uid1", Name: "name1", Type: "custom", Settings: simplejson.NewFromAny(map[string]any{"empty": true})}
+
+		_, err := m.createNotifier(c)
+		require.ErrorContains(t, err, "failed to migrate notification channel \"name1\" of type custom: no notifier returned")
+	})
+
+	t.Run("notifier without type from registered migrator is an error", func(t *testing.T) {
+		m := newTestMigration(t)
+		c := &NotificationChannel{Uid: "uid1", Name: "name1", Type: "custom", Settings: simplejson.NewFromAny(map[string]any{"untyped": true})}
+
+		_, err := m.createNotifier(c)
+		require.ErrorContains(t, err, "failed to migrate notification channel \"name1\" of type custom: notifier has no type")
+	})
+
+	t.Run("notifier without settings from registered migrator gets empty settings", func(t *testing.T) {
+		m := newTestMigration(t)
+		c := &NotificationChannel{Uid: "uid1", Name: "name1", Type: "custom", Settings: simplejson.NewFromAny(map[string]any{"noSettings": true})}
+
+		notifier, err := m.createNotifier(c)
+		require.NoError(t, err)
+		require.NotNil(t, notifier.Settings)
+		require.Empty(t, notifier.Settings.MustMap())
+		// Validation fails on the missing addresses instead of panicking.
+		require.ErrorContains(t, m.validateIntegration(notifier), "could not find addresses in settings")
+	})
+
+	t.Run("disable resolve message is copied from the channel", func(t *testing.T) {
+		m := newTestMigration(t)
+		c := &NotificationChannel{Uid: "uid1", Name: "name1", Type: "custom", DisableResolveMessage: true, Settings: simplejson.NewFromAny(map[string]any{"endpoint": "http://localhost"})}
+
+		notifier, err := m.createNotifier(c)
+		require.NoError(t, err)
+		require.True(t, notifier.DisableResolveMessage)
+	})
+
+	t.Run("registered migrator gets decrypted secure settings", func(t *testing.T) {
+		m := newTestMigration(t)
+		secureSettings := GetEncryptedJsonData(map[string]string{"token": "secret"})
+		secureSettings["other"] = []byte("not encrypted")
+		c := &NotificationChannel{Uid: "uid1", Name: "name1", Type: "custom", Settings: simplejson.NewFromAny(map[string]any{"endpoint": "http://localhost"}), SecureSettings: secureSettings}
+
+		notifier, err := m.createNotifier(c)
+		require.NoError(t, err)
+		require.Contains(t, notifier.SecureSettings, "token")
+		require.NotContains(t, notifier.SecureSettings, "other")
+	})
+
+	t.Run("secure settings from registered migrator are encrypted", func(t *testing.T) {
+		m := newTestMigration(t)
+		c := &NotificationChannel{Uid: "uid1", Name: "name1", Type: "custom", Settings: simplejson.NewFromAny(map[string]any{"endpoint": "http://localhost"}), SecureSettings: GetEncryptedJsonData(map[string]string{"password": "secret"})}
+
+		notifier, err := m.createNotifier(c)
+		require.NoError(t, err)
+		encrypted, err := base64.StdEncoding.DecodeString(notifier.SecureSettings["password"])
+		require.NoError(t, err)
+		decrypted, err := util.Decrypt(encrypted, setting.SecretKey)
+		require.NoError(t, err)
+		require.Equal(t, "secret", string(decrypted))
+		require.NoError(t, m.validateIntegration(notifier))
+	})
+
+	t.Run("built-in migration is used for other channel types", func(t *testing.T) {
+		m := newTestMigration(t)
+		c := &NotificationChannel{Uid: "uid1", Name: "name1", Type: "email", Settings: simplejson.NewFromAny(map[string]any{"addresses": "test@example.com"})}
+
+		notifier, err := m.createNotifier(c)
+		require.NoError(t, err)
+		require.Equal(t, "email", notifier.Type)
+		require.Equal(t, "test@example.com", notifier.Settings.Get("addresses").MustString())
+	})
+}
+
+func TestMigrateSettingsToSecureSettings(t *testing.T) {
+	t.Run("secure settings that cannot be decrypted are left out", func(t *testing.T) {
+		secureSettings := GetEncryptedJsonData(map[string]string{"token": "secret-token"})
+		secureSettings["url"] = []byte("not encrypted")
+		l := &logtest.Fake{}
+
+		settings, newSecureSettings, err := migrateSettingsToSecureSettings(l, "slack", simplejson.NewFromAny(map[string]any{"recipient": "#alerts"}), secureSettings)
+		require.NoError(t, err)
+
+		require.Equal(t, "#alerts", settings.Get("recipient").MustString())
+		require.Contains(t, newSecureSettings, "token")
+		require.NotContains(t, newSecureSettings, "url")
+		require.Equal(t, 1, l.WarnLogs.Calls)
+		require.Equal(t, []any{"settings", []string{"url"}}, l.WarnLogs.Ctx)
+	})
+
+	t.Run("plain settings are moved to secure settings", func(t *testing.T) {
+		l := &logtest.Fake{}
+
+		settings, newSecureSettings, err := migrateSettingsToSecureSettings(l, "slack", simplejson.NewFromAny(map[string]any{"url": "http://localhost"}), SecureJsonData{})
+		require.NoError(t, err)
+
+		require.Empty(t, settings.MustMap())
+		require.Contains(t, newSecureSettings, "url")
+		require.Zero(t, l.WarnLogs.Calls)
+	})
+}
+
+func TestValidateNotifiersWithUndecryptableSecureSettings(t *testing.T) {
+	undecryptable := SecureJsonData{"password": []byte("not encrypted"), "url": []byte("not encrypted")}
+
+	tc := []struct {
+		name          string
+		channel       *NotificationChannel
+		ignoreInvalid bool
+		expNotifiers  int
+		expErr        string
+	}{
+		{
+			name:         "channel without required secrets is migrated",
+			channel:      &NotificationChannel{Uid: "uid1", Name: "webhook", Type: "webhook", Settings: simplejson.NewFromAny(map[string]any{"url": "http://localhost"}), SecureSettings: undecryptable},
+			expNotifiers: 1,
+		},
+		{
+			name:    "channel with required secrets fails validation",
+			channel: &NotificationChannel{Uid: "uid1", Name: "slack", Type: "slack", Settings: simplejson.NewFromAny(map[string]any{"recipient": "#alerts"}), SecureSettings: undecryptable},
+			expErr:  "failed to validate integration \"slack\" (UID uid1) of type \"slack\"",
+		},
+		{
+			name:          "channel with required secrets is removed if invalid channels are ignored",
+			channel:       &NotificationChannel{Uid: "uid1", Name: "slack", Type: "slack", Settings: simplejson.NewFromAny(map[string]any{"recipient": "#alerts"}), SecureSettings: undecryptable},
+			ignoreInvalid: true,
+			expNotifiers:  0,
+		},
+	}
+
+	for _, tt := range tc {
+		t.Run(tt.name, func(t *testing.T) {
+			m := newTestMigration(t)
+			m.mg.Cfg.UnifiedAlerting.UpgradeIgnoreInvalidChannels = tt.ignoreInvalid
+
+			notifier, err := m.createNotifier(tt.channel)
+			require.NoError(t, err)
+			require.NotContains(t, notifier.SecureSettings, "url")
+
+			config := configFromReceivers(t, []*PostableGrafanaReceiver{notifier})
+			err = m.validateAlertmanagerConfig(config)
+			if tt.expErr != "" {
+				require.ErrorContains(t, err, tt.expErr)
+				return
+			}
+			require.NoError(t, err)
+			require.Len(t, config.AlertmanagerConfig.Receivers[0].GrafanaManagedReceivers, tt.expNotifiers)
+		})
+	}
+}
@@ -7,7 +7,10 @@ import (
 	"strings"
 	"time"
 
+	"github.com/grafana/grafana-plugin-sdk-go/backend/gtime"
+
 	"github.com/grafana/grafana/pkg/components/simplejson"
+	"github.com/grafana/grafana/pkg/expr"
 	"github.com/grafana/grafana/pkg/services/datasources"
 	"github.com/grafana/grafana/pkg/tsdb/legacydata"
 	"github.com/grafana/grafana/pkg/tsdb/legacydata/interval"
@@ -18,7 +21,7 @@ func transConditions(set dashAlertSettings, orgID int64, dsUIDMap dsUIDLookup) (
 	refIDtoCondIdx := make(map[string][]int) // a map of original refIds to their corresponding condition index
 	for i, cond := range set.Conditions {
 		if len(cond.Query.Params) != 3 {
-			return nil, fmt.Errorf("unexpected number of query parameters in cond %v, want 3 got %v", i+1, len(cond.Query.Params))
+			return nil, fmt.Errorf("%w: unexpected number of query parameters in cond %v, want 3 got %v", ErrConditionUnsupported, i+1, len(cond.Query.Params))
 		}
 		refID := cond.Query.Params[0]
 		refIDtoCondIdx[refID] = append(refIDtoCondIdx[refID], i)
@@ -125,6 +128,10 @@ func transConditions(set dashAlertSettings, orgID int64, dsUIDMap dsUIDLookup) (
 
 			// one could have an alert saved but datasource deleted, so can not require match.
 			dsUID := dsUIDMap.GetUID(orgID, set.Conditions[condIdx].Query.DatasourceID)
+			if set.Conditions[condIdx].Query.DatasourceID == expr.DatasourceID {
+				// the query explicitly references the expression datasource, which is not stored in the data_source table.
+				dsUID = expr.DatasourceUID
+			}
 			queryObj["refId"] = refID
 
 			// See services/alerting/conditions/query.go's newQueryCondition
@@ -150,7 +157,7 @@ func transConditions(set dashAlertSettings, orgID int64, dsUIDMap dsUIDLookup) (
 
 			rTR, err := getRelativeDuration(rawFrom, rawTo)
 			if err != nil {
-				return nil, err
+				return nil, fmt.Errorf("%w: invalid time range of query %s: %s", ErrConditionUnsupported, set.Conditions[condIdx].Query.Params[0], err)
 			}
 
 			alertQuery := alertQuery{
@@ -204,7 +211,7 @@ func transConditions(set dashAlertSettings, orgID int64, dsUIDMap dsUIDLookup) (
 	ccAlertQuery := alertQuery{
 		RefID:         ccRefID,
 		Model:         exprModelJSON,
-		DatasourceUID: expressionDatasourceUID,
+		DatasourceUID: expr.DatasourceUID,
 	}
 
 	newCond.Data = append(newCond.Data, ccAlertQuery)
@@ -267,33 +274,40 @@ func getRelativeDuration(rawFrom, rawTo string) (*relativeTimeRange, error) {
 }
 
 func getFrom(from string) (time.Duration, error) {
-	fromRaw := strings.Replace(from, "now-", "", 1)
-
-	d, err := time.ParseDuration("-" + fromRaw)
+	d, err := parseRelativeTime(strings.TrimPrefix(from, "now-"))
 	if err != nil {
-		return 0, err
+		return 0, fmt.Errorf("unsupported from time %q: %w", from, err)
 	}
-	return -d, err
+	return d, nil
 }
 
+// getTo parses the end of a legacy time range. A bare duration such as "1m" means the same as "now-1m", like it does
+// for the start of the range.
 func getTo(to string) (time.Duration, error) {
 	if to == "now" {
 		return 0, nil
-	} else if strings.HasPrefix(to, "now-") {
-		withoutNow := strings.Replace(to, "now-", "", 1)
+	}
 
-		d, err := time.ParseDuration("-" + withoutNow)
-		if err != nil {
-			return 0, err
-		}
-		return -d, nil
+	d, err := parseRelativeTime(strings.TrimPrefix(to, "now-"))
+	if err != nil {
+		return 0, fmt.Errorf("unsupported to time %q: %w", to, err)
 	}
+	return d, nil
+}
 
-	d, err := time.ParseDuration(to)
+// parseRelativeTime parses the duration of a legacy relative time such as "5m". Legacy alerting only accepted durations
+// that time.ParseDuration can parse. The d, w, M and y units are an extension that legacy alerting never accepted, M
+// and y are approximated with their average length in the Julian calendar, 365.25/12 and 365.25 days. Rounded and
+// absolute times, such as "now/d", cannot be expressed as a relative time range and are rejected.
+func parseRelativeTime(s string) (time.Duration, error) {
+	d, err := gtime.ParseDuration(s)
 	if err != nil {
 		return 0, err
 	}
-	return -d, nil
+	if d < 0 {
+		return 0, fmt.Errorf("negative duration %s", d)
+	}
+	return d, nil
 }
 
 type classicConditionJSON struct {
@@ -0,0 +1,147 @@
+package ualert
+
+import (
+	"encoding/json"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/grafana/grafana/pkg/expr"
+)
+
+func TestTransConditions(t *testing.T) {
+	newSettings := func(t *testing.T, datasourceID int64) dashAlertSettings {
+		t.Helper()
+		var settings dashAlertSettings
+		require.NoError(t, json.Unmarshal([]byte(`{
+			"conditions": [{
+				"evaluator": {"params": [1], "type": "gt"},
+				"operator": {"type": "and"},
+				"query": {"params": ["A", "5m", "now"], "model": {"refId": "A"}},
+				"reducer": {"type": "avg"}
+			}]
+		}`), &settings))
+		settings.Conditions[0].Query.DatasourceID = datasourceID
+		return settings
+	}
+
+	t.Run("query datasource is resolved from the lookup", func(t *testing.T) {
+		cond, err := transConditions(newSettings(t, 1), 1, dsUIDLookup{{1, 1}: "ds-uid"})
+		require.NoError(t, err)
+
+		require.Equal(t, "B", cond.Condition)
+		require.Len(t, cond.Data, 2)
+		require.Equal(t, "ds-uid", cond.Data[0].DatasourceUID)
+		require.Equal(t, expr.DatasourceUID, cond.Data[1].DatasourceUID)
+	})
+
+	t.Run("unsupported time range reports the query", func(t *testing.T) {
+		settings := newSettings(t, 1)
+		settings.Conditions[0].Query.Params[1] = "now/d"
+
+		_, err := transConditions(settings, 1, dsUIDLookup{{1, 1}: "ds-uid"})
+		require.ErrorIs(t, err, ErrConditionUnsupported)
+		require.ErrorContains(t, err, `invalid time range of query A: unsupported from time "now/d"`)
+	})
+
+	t.Run("condition with missing query parameters is unsupported", func(t *testing.T) {
+		settings := newSettings(t, 1)
+		settings.Conditions[0].Query.Params = settings.Conditions[0].Query.Params[:1]
+
+		_, err := transConditions(settings, 1, dsUIDLookup{{1, 1}: "ds-uid"})
+		require.ErrorIs(t, err, ErrConditionUnsupported)
+	})
+
+	t.Run("query referencing the expression datasource uses its UID", func(t *testing.T) {
+		cond, err := transConditions(newSettings(t, expr.DatasourceID), 1, dsUIDLookup{})
+		require.NoError(t, err)
+
+		require.Len(t, cond.Data, 2)
+		require.Equal(t, expr.DatasourceUID, cond.Data[0].DatasourceUID)
+	})
+}
+
+func TestGetRelativeDuration(t *testing.T) {
+	tc := []struct {
+		name    string
+		from    string
+		to      string
+		expFrom time.Duration
+		expTo   time.Duration
+		expErr  string
+	}{
+		{
+			name:    "duration and now",
+			from:    "5m",
+			to:      "now",
+			expFrom: 5 * time.Minute,
+			expTo:   0,
+		},
+		{
+			name:    "relative to now",
+			from:    "now-1h",
+			to:      "now-5m",
+			expFrom: time.Hour,
+			expTo:   5 * time.Minute,
+		},
+		{
+			name:    "duration to",
+			from:    "10m",
+			to:      "1m",
+			expFrom: 10 * time.Minute,
+			expTo:   time.Minute,
+		},
+		{
+			name:    "day and week units",
+			from:    "now-1w",
+			to:      "1d",
+			expFrom: 7 * 24 * time.Hour,
+			expTo:   24 * time.Hour,
+		},
+		{
+			name:    "month and year units use their average length",
+			from:    "now-1y",
+			to:      "now-1M",
+			expFrom: 8766 * time.Hour,
+			expTo:   8766 * time.Hour / 12,
+		},
+		{
+			name:   "rounded from time is not supported",
+			from:   "now/d",
+			to:     "now",
+			expErr: `unsupported from time "now/d"`,
+		},
+		{
+			name:   "rounded to time is not supported",
+			from:   "now-1d/d",
+			to:     "now",
+			expErr: `unsupported from time "now-1d/d"`,
+		},
+		{
+			name:   "absolute to time is not supported",
+			from:   "5m",
+			to:     "1600000000000",
+			expErr: `unsupported to time "1600000000000"`,
+		},
+		{
+			name:   "negative duration is not supported",
+			from:   "-5m",
+			to:     "now",
+			expErr: `unsupported from time "-5m": negative duration -5m0s`,
+		},
+	}
+
+	for _, tt := range tc {
+		t.Run(tt.name, func(t *testing.T) {
+			rtr, err := getRelativeDuration(tt.from, tt.to)
+			if tt.expErr != "" {
+				require.ErrorContains(t, err, tt.expErr)
+				return
+			}
+			require.NoError(t, err)
+			require.Equal(t, duration(tt.expFrom), rtr.From)
+			require.Equal(t, duration(tt.expTo), rtr.To)
+		})
+	}
+}
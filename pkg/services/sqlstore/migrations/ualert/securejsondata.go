@@ -2,6 +2,7 @@ package ualert
 
 import (
 	"os"
+	"sort"
 
 	"github.com/grafana/grafana/pkg/infra/log"
 	"github.com/grafana/grafana/pkg/setting"
@@ -28,20 +29,23 @@ func (s SecureJsonData) DecryptedValue(key string) (string, bool) {
 	return "", false
 }
 
-// Decrypt returns map of the same type but where the all the values are decrypted. Opposite of what
-// GetEncryptedJsonData is doing.
-func (s SecureJsonData) Decrypt() map[string]string {
+// decryptValues returns a map where all the values are decrypted, opposite of what GetEncryptedJsonData is doing. Unlike
+// DecryptedValue it does not exit if a value cannot be decrypted, for example because the secret key was changed. It
+// returns the values that could be decrypted and the sorted keys of the values that could not.
+func (s SecureJsonData) decryptValues() (map[string]string, []string) {
 	decrypted := make(map[string]string)
+	var failed []string
 	for key, data := range s {
 		decryptedData, err := util.Decrypt(data, setting.SecretKey)
 		if err != nil {
-			seclogger.Error(err.Error())
-			os.Exit(1)
+			failed = append(failed, key)
+			continue
 		}
 
 		decrypted[key] = string(decryptedData)
 	}
-	return decrypted
+	sort.Strings(failed)
+	return decrypted, failed
 }
 
 // GetEncryptedJsonData returns map where all keys are encrypted.
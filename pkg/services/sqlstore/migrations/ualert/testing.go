@@ -5,6 +5,7 @@ import (
 
 	"github.com/grafana/grafana/pkg/infra/log"
 	"github.com/grafana/grafana/pkg/services/sqlstore/migrator"
+	"github.com/grafana/grafana/pkg/setting"
 	"github.com/prometheus/alertmanager/silence/silencepb"
 )
 
@@ -14,8 +15,13 @@ func newTestMigration(t *testing.T) *migration {
 
 	return &migration{
 		mg: &migrator.Migrator{
-
 			Logger: log.New("test"),
+			Cfg: &setting.Cfg{
+				UnifiedAlerting: setting.UnifiedAlertingSettings{
+					BaseInterval: setting.SchedulerBaseInterval,
+					MinInterval:  setting.SchedulerBaseInterval,
+				},
+			},
 		},
 		seenUIDs: uidSet{
 			set: make(map[string]struct{}),
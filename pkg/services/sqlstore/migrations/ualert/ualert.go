@@ -2,6 +2,8 @@ package ualert
 
 import (
 	"context"
+	"crypto/sha256"
+	"encoding/hex"
 	"encoding/json"
 	"errors"
 	"fmt"
@@ -15,6 +17,7 @@ import (
 	pb "github.com/prometheus/alertmanager/silence/silencepb"
 	"xorm.io/xorm"
 
+	"github.com/grafana/grafana/pkg/infra/log"
 	ngmodels "github.com/grafana/grafana/pkg/services/ngalert/models"
 	"github.com/grafana/grafana/pkg/services/sqlstore/migrator"
 	"github.com/grafana/grafana/pkg/setting"
@@ -40,8 +43,15 @@ var rmMigTitle = "remove unified alerting data"
 const clearMigrationEntryTitle = "clear migration entry %q"
 const codeMigration = "code migration"
 
-// It is defined in pkg/expr/service.go as "DatasourceType"
-const expressionDatasourceUID = "__expr__"
+var (
+	// ErrDashboardNotFound is returned when the dashboard of a legacy alert does not exist.
+	ErrDashboardNotFound = errors.New("dashboard not found")
+	// ErrConditionUnsupported is returned when a legacy alert condition cannot be translated into unified alerting.
+	ErrConditionUnsupported = errors.New("unsupported alert condition")
+	// ErrIntervalBelowMinimum is returned when a legacy alert is evaluated more often than the minimum evaluation
+	// interval and the migration is configured to fail instead of clamping the interval.
+	ErrIntervalBelowMinimum = errors.New("alert frequency is lower than the minimum evaluation interval")
+)
 
 type MigrationError struct {
 	AlertId int64
@@ -52,7 +62,7 @@ func (e MigrationError) Error() string {
 	return fmt.Sprintf("failed to migrate alert %d: %s", e.AlertId, e.Err.Error())
 }
 
-func (e *MigrationError) Unwrap() error { return e.Err }
+func (e MigrationError) Unwrap() error { return e.Err }
 
 func AddDashAlertMigration(mg *migrator.Migrator) {
 	logs, err := mg.GetMigrationLog()
@@ -296,7 +306,10 @@ func (m *migration) Exec(sess *xorm.Session, mg *migrator.Migrator) error {
 		l.Debug("Migrating alert rule to Unified Alerting")
 		newCond, err := transConditions(*da.ParsedSettings, da.OrgId, dsIDMap)
 		if err != nil {
-			return err
+			return MigrationError{
+				Err:     fmt.Errorf("failed to translate conditions: %w", err),
+				AlertId: da.Id,
+			}
 		}
 
 		da.DashboardUID = dashIDMap[[2]int64{da.OrgId, da.DashboardId}]
@@ -312,7 +325,7 @@ func (m *migration) Exec(sess *xorm.Session, mg *migrator.Migrator) error {
 		}
 		if !exists {
 			return MigrationError{
-				Err:     fmt.Errorf("dashboard with UID %v under organisation %d not found: %w", da.DashboardUID, da.OrgId, err),
+				Err:     fmt.Errorf("%w: UID %v under organisation %d", ErrDashboardNotFound, da.DashboardUID, da.OrgId),
 				AlertId: da.Id,
 			}
 		}
@@ -377,7 +390,10 @@ func (m *migration) Exec(sess *xorm.Session, mg *migrator.Migrator) error {
 		}
 		rule, err := m.makeAlertRule(l, *newCond, da, folder.Uid)
 		if err != nil {
-			return fmt.Errorf("failed to migrate alert rule '%s' [ID:%d, DashboardUID:%s, orgID:%d]: %w", da.Name, da.Id, da.DashboardUID, da.OrgId, err)
+			return MigrationError{
+				Err:     fmt.Errorf("failed to migrate alert rule '%s' [DashboardUID:%s, orgID:%d]: %w", da.Name, da.DashboardUID, da.OrgId, err),
+				AlertId: da.Id,
+			}
 		}
 
 		if _, ok := rulesPerOrg[rule.OrgID]; !ok {
@@ -451,12 +467,29 @@ func (m *migration) insertRules(mg *migrator.Migrator, rulesPerOrg map[int64]map
 	return nil
 }
 
+const (
+	// mysqlMaxAlertmanagerConfigSize is the size limit of the MEDIUMTEXT alertmanager_configuration column on MySQL.
+	// The column has no practical size limit on other databases.
+	mysqlMaxAlertmanagerConfigSize = 1<<24 - 1
+	// largeAlertmanagerConfigSize is the size above which a warning is logged, as the Alertmanager can be slow to load
+	// configurations with thousands of receivers and routes.
+	largeAlertmanagerConfigSize = 1 << 20
+)
+
 func (m *migration) writeAlertmanagerConfig(orgID int64, amConfig *PostableUserConfig) error {
 	rawAmConfig, err := json.Marshal(amConfig)
 	if err != nil {
 		return err
 	}
 
+	maxSize := 0
+	if m.mg.Dialect.DriverName() == migrator.MySQL {
+		maxSize = mysqlMaxAlertmanagerConfigSize
+	}
+	if err := checkAlertmanagerConfigSize(m.mg.Logger.New("org", orgID), amConfig, len(rawAmConfig), maxSize); err != nil {
+		return fmt.Errorf("failed to write AlertmanagerConfig in orgId %d: %w", orgID, err)
+	}
+
 	// remove an existing configuration, which could have been left during switching back to legacy alerting
 	_, _ = m.sess.Delete(AlertConfiguration{OrgID: orgID})
 
@@ -475,50 +508,77 @@ func (m *migration) writeAlertmanagerConfig(orgID int64, amConfig *PostableUserC
 	return nil
 }
 
+// checkAlertmanagerConfigSize returns an error if the size of the serialized configuration exceeds maxSize, which is
+// ignored if 0, and warns if the configuration is large enough to slow down the Alertmanager.
+func checkAlertmanagerConfigSize(l log.Logger, amConfig *PostableUserConfig, size int, maxSize int) error {
+	receivers := len(amConfig.AlertmanagerConfig.Receivers)
+	if maxSize > 0 && size > maxSize {
+		return fmt.Errorf("migrated Alertmanager configuration with %d receivers is %d bytes, which exceeds the maximum of %d bytes that can be stored", receivers, size, maxSize)
+	}
+	if size > largeAlertmanagerConfigSize {
+		l.Warn("Migrated Alertmanager configuration is large and can be slow to load", "receivers", receivers, "bytes", size)
+	}
+	return nil
+}
+
 // validateAlertmanagerConfig validates the alertmanager configuration produced by the migration against the receivers.
+// If invalid channels are ignored, integrations that fail validation are removed from their receiver with a warning
+// instead of failing the migration.
 func (m *migration) validateAlertmanagerConfig(config *PostableUserConfig) error {
 	for _, r := range config.AlertmanagerConfig.Receivers {
+		valid := r.GrafanaManagedReceivers[:0]
 		for _, gr := range r.GrafanaManagedReceivers {
-			data, err := gr.Settings.MarshalJSON()
-			if err != nil {
-				return err
-			}
-			var (
-				cfg = &alertingNotify.GrafanaIntegrationConfig{
-					UID:                   gr.UID,
-					Name:                  gr.Name,
-					Type:                  gr.Type,
-					DisableResolveMessage: gr.DisableResolveMessage,
-					Settings:              data,
-					SecureSettings:        gr.SecureSettings,
-				}
-			)
-
-			// decryptFunc represents the legacy way of decrypting data. Before the migration, we don't need any new way,
-			// given that the previous alerting will never support it.
-			decryptFunc := func(_ context.Context, sjd map[string][]byte, key string, fallback string) string {
-				if value, ok := sjd[key]; ok {
-					decryptedData, err := util.Decrypt(value, setting.SecretKey)
-					if err != nil {
-						m.mg.Logger.Warn("Unable to decrypt key '%s' for %s receiver with uid %s, returning fallback.", key, gr.Type, gr.UID)
-						return fallback
-					}
-					return string(decryptedData)
+			if err := m.validateIntegration(gr); err != nil {
+				if !m.mg.Cfg.UnifiedAlerting.UpgradeIgnoreInvalidChannels {
+					return err
 				}
-				return fallback
-			}
-			_, err = alertingNotify.BuildReceiverConfiguration(context.Background(), &alertingNotify.APIReceiver{
-				GrafanaIntegrations: alertingNotify.GrafanaIntegrations{Integrations: []*alertingNotify.GrafanaIntegrationConfig{cfg}},
-			}, decryptFunc)
-			if err != nil {
-				return err
+				m.mg.Logger.Warn("Removing legacy channel that is not valid in unified alerting from its contact point", "contactPoint", r.Name, "name", gr.Name, "type", gr.Type, "uid", gr.UID, "error", err)
+				continue
 			}
+			valid = append(valid, gr)
 		}
+		r.GrafanaManagedReceivers = valid
 	}
 
 	return nil
 }
 
+// validateIntegration validates a single migrated integration by building its notifier configuration.
+func (m *migration) validateIntegration(gr *PostableGrafanaReceiver) error {
+	data, err := gr.Settings.MarshalJSON()
+	if err != nil {
+		return err
+	}
+	var (
+		cfg = &alertingNotify.GrafanaIntegrationConfig{
+			UID:                   gr.UID,
+			Name:                  gr.Name,
+			Type:                  gr.Type,
+			DisableResolveMessage: gr.DisableResolveMessage,
+			Settings:              data,
+			SecureSettings:        gr.SecureSettings,
+		}
+	)
+
+	// decryptFunc represents the legacy way of decrypting data. Before the migration, we don't need any new way,
+	// given that the previous alerting will never support it.
+	decryptFunc := func(_ context.Context, sjd map[string][]byte, key string, fallback string) string {
+		if value, ok := sjd[key]; ok {
+			decryptedData, err := util.Decrypt(value, setting.SecretKey)
+			if err != nil {
+				m.mg.Logger.Warn("Unable to decrypt key '%s' for %s receiver with uid %s, returning fallback.", key, gr.Type, gr.UID)
+				return fallback
+			}
+			return string(decryptedData)
+		}
+		return fallback
+	}
+	_, err = alertingNotify.BuildReceiverConfiguration(context.Background(), &alertingNotify.APIReceiver{
+		GrafanaIntegrations: alertingNotify.GrafanaIntegrations{Integrations: []*alertingNotify.GrafanaIntegrationConfig{cfg}},
+	}, decryptFunc)
+	return err
+}
+
 type AlertConfiguration struct {
 	ID    int64 `xorm:"pk autoincr 'id'"`
 	OrgID int64 `xorm:"org_id"`
@@ -923,3 +983,19 @@ func (s *uidSet) generateUid() (string, error) {
 
 	return "", errors.New("failed to generate UID")
 }
+
+// deterministicUidLength is the length of the uids generated by generateDeterministicUid.
+const deterministicUidLength = 14
+
+// generateDeterministicUid will generate a uid derived from the given key, so that the same key always results in the
+// same uid. The uid only contains lower case characters so it is also unique when compared in a case-insensitive manner.
+// If the derived uid has already been generated in this uidSet, a random uid is generated instead.
+func (s *uidSet) generateDeterministicUid(key string) (string, error) {
+	sum := sha256.Sum256([]byte(key))
+	uid := hex.EncodeToString(sum[:])[:deterministicUidLength]
+	if !s.contains(uid) {
+		s.add(uid)
+		return uid, nil
+	}
+	return s.generateUid()
+}
@@ -12,6 +12,7 @@ import (
 	"github.com/stretchr/testify/require"
 
 	"github.com/grafana/grafana/pkg/components/simplejson"
+	"github.com/grafana/grafana/pkg/infra/log/logtest"
 	"github.com/grafana/grafana/pkg/util"
 )
 
@@ -57,9 +58,11 @@ func (m *ObjectMatchers) UnmarshalJSON(data []byte) error {
 
 func Test_validateAlertmanagerConfig(t *testing.T) {
 	tc := []struct {
-		name      string
-		receivers []*PostableGrafanaReceiver
-		err       error
+		name          string
+		receivers     []*PostableGrafanaReceiver
+		ignoreInvalid bool
+		expReceivers  []string
+		err           error
 	}{
 		{
 			name: "when a slack receiver does not have a valid URL - it should error",
@@ -74,6 +77,27 @@ func Test_validateAlertmanagerConfig(t *testing.T) {
 			},
 			err: fmt.Errorf("failed to validate integration \"SlackWithBadURL\" (UID test-uid) of type \"slack\": invalid URL %q", invalidUri),
 		},
+		{
+			name: "when invalid channels are ignored - it should remove them and not error",
+			receivers: []*PostableGrafanaReceiver{
+				{
+					UID:            "test-uid",
+					Name:           "SlackWithBadURL",
+					Type:           "slack",
+					Settings:       simplejson.NewFromAny(map[string]interface{}{}),
+					SecureSettings: map[string]string{"url": invalidUri},
+				},
+				{
+					UID:            util.GenerateShortUID(),
+					Name:           "SlackWithGoodURL",
+					Type:           "slack",
+					Settings:       simplejson.NewFromAny(map[string]interface{}{"recipient": "#a-good-channel"}),
+					SecureSettings: map[string]string{"url": "http://webhook.slack.com/myuser"},
+				},
+			},
+			ignoreInvalid: true,
+			expReceivers:  []string{"SlackWithGoodURL"},
+		},
 		{
 			name: "when a slack receiver has an invalid recipient - it should not error",
 			receivers: []*PostableGrafanaReceiver{
@@ -103,6 +127,7 @@ func Test_validateAlertmanagerConfig(t *testing.T) {
 	for _, tt := range tc {
 		t.Run(tt.name, func(t *testing.T) {
 			mg := newTestMigration(t)
+			mg.mg.Cfg.UnifiedAlerting.UpgradeIgnoreInvalidChannels = tt.ignoreInvalid
 
 			config := configFromReceivers(t, tt.receivers)
 			require.NoError(t, config.EncryptSecureSettings()) // make sure we encrypt the settings
@@ -113,6 +138,14 @@ func Test_validateAlertmanagerConfig(t *testing.T) {
 			} else {
 				require.NoError(t, err)
 			}
+
+			if tt.expReceivers != nil {
+				names := make([]string, 0, len(tt.expReceivers))
+				for _, gr := range config.AlertmanagerConfig.Receivers[0].GrafanaManagedReceivers {
+					names = append(names, gr.Name)
+				}
+				require.Equal(t, tt.expReceivers, names)
+			}
 		})
 	}
 }
@@ -192,3 +225,76 @@ func Test_shortUIDCaseInsensitiveConflicts(t *testing.T) {
 
 	require.Equal(t, len(s.set), len(deduped))
 }
+
+func Test_generateDeterministicUid(t *testing.T) {
+	t.Run("same key generates the same uid", func(t *testing.T) {
+		s1 := uidSet{set: make(map[string]struct{})}
+		s2 := uidSet{set: make(map[string]struct{})}
+
+		uid1, err := s1.generateDeterministicUid("1-dash-1")
+		require.NoError(t, err)
+		uid2, err := s2.generateDeterministicUid("1-dash-1")
+		require.NoError(t, err)
+
+		require.Equal(t, uid1, uid2)
+		require.Len(t, uid1, deterministicUidLength)
+		require.Equal(t, strings.ToLower(uid1), uid1)
+	})
+
+	t.Run("different keys generate different uids", func(t *testing.T) {
+		s := uidSet{set: make(map[string]struct{})}
+
+		uid1, err := s.generateDeterministicUid("1-dash-1")
+		require.NoError(t, err)
+		uid2, err := s.generateDeterministicUid("1-dash-2")
+		require.NoError(t, err)
+
+		require.NotEqual(t, uid1, uid2)
+	})
+
+	t.Run("duplicate key falls back to a random uid", func(t *testing.T) {
+		s := uidSet{set: make(map[string]struct{})}
+
+		uid1, err := s.generateDeterministicUid("1-dash-1")
+		require.NoError(t, err)
+		uid2, err := s.generateDeterministicUid("1-dash-1")
+		require.NoError(t, err)
+
+		require.NotEqual(t, uid1, uid2)
+		require.True(t, s.contains(uid2))
+	})
+}
+
+func TestMigrationError(t *testing.T) {
+	err := error(MigrationError{
+		Err:     fmt.Errorf("failed to translate conditions: %w", ErrConditionUnsupported),
+		AlertId: 1,
+	})
+
+	require.EqualError(t, err, "failed to migrate alert 1: failed to translate conditions: unsupported alert condition")
+	require.ErrorIs(t, err, ErrConditionUnsupported)
+	var migrationErr MigrationError
+	require.ErrorAs(t, err, &migrationErr)
+	require.Equal(t, int64(1), migrationErr.AlertId)
+}
+
+func TestCheckAlertmanagerConfigSize(t *testing.T) {
+	config := configFromReceivers(t, nil)
+
+	t.Run("config within limits", func(t *testing.T) {
+		l := &logtest.Fake{}
+		require.NoError(t, checkAlertmanagerConfigSize(l, config, 1000, mysqlMaxAlertmanagerConfigSize))
+		require.Zero(t, l.WarnLogs.Calls)
+	})
+
+	t.Run("large config warns", func(t *testing.T) {
+		l := &logtest.Fake{}
+		require.NoError(t, checkAlertmanagerConfigSize(l, config, largeAlertmanagerConfigSize+1, 0))
+		require.Equal(t, 1, l.WarnLogs.Calls)
+	})
+
+	t.Run("config exceeding the maximum size fails", func(t *testing.T) {
+		err := checkAlertmanagerConfigSize(&logtest.Fake{}, config, mysqlMaxAlertmanagerConfigSize+1, mysqlMaxAlertmanagerConfigSize)
+		require.EqualError(t, err, "migrated Alertmanager configuration with 1 receivers is 16777216 bytes, which exceeds the maximum of 16777215 bytes that can be stored")
+	})
+}
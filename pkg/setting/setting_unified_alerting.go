@@ -9,6 +9,7 @@ import (
 
 	"github.com/grafana/grafana-plugin-sdk-go/backend/gtime"
 	"github.com/prometheus/alertmanager/cluster"
+	"github.com/prometheus/common/model"
 	"gopkg.in/ini.v1"
 
 	"github.com/grafana/grafana/pkg/util"
@@ -98,6 +99,15 @@ type UnifiedAlertingSettings struct {
 	RemoteAlertmanager            RemoteAlertmanagerSettings
 	// MaxStateSaveConcurrency controls the number of goroutines (per rule) that can save alert state in parallel.
 	MaxStateSaveConcurrency int
+	// UpgradeGroupBy is the list of labels to group by in the root route created by the legacy alerting upgrade.
+	// If empty, the upgrade groups by folder and alert rule name.
+	UpgradeGroupBy []string
+	// UpgradeIgnoreInvalidChannels makes the legacy alerting upgrade remove notification channels that are not valid in
+	// unified alerting from their contact point instead of failing.
+	UpgradeIgnoreInvalidChannels bool
+	// UpgradeFailBelowMinInterval makes the legacy alerting upgrade fail for alerts with a frequency lower than
+	// MinInterval instead of clamping their evaluation interval to it.
+	UpgradeFailBelowMinInterval bool
 }
 
 // RemoteAlertmanagerSettings contains the configuration needed
@@ -399,10 +409,39 @@ func (cfg *Cfg) ReadUnifiedAlertingSettings(iniFile *ini.File) error {
 
 	uaCfg.MaxStateSaveConcurrency = ua.Key("max_state_save_concurrency").MustInt(1)
 
+	uaCfg.UpgradeGroupBy = util.SplitString(ua.Key("upgrade_group_by").MustString(""))
+	if err := validateUpgradeGroupBy(uaCfg.UpgradeGroupBy); err != nil {
+		return fmt.Errorf("invalid value of setting 'upgrade_group_by': %w", err)
+	}
+	uaCfg.UpgradeIgnoreInvalidChannels = ua.Key("upgrade_ignore_invalid_channels").MustBool(false)
+	uaCfg.UpgradeFailBelowMinInterval = ua.Key("upgrade_fail_below_min_interval").MustBool(false)
+
 	cfg.UnifiedAlerting = uaCfg
 	return nil
 }
 
+// validateUpgradeGroupBy checks the labels to group by in the migrated root route the same way the Alertmanager
+// does when it loads the configuration, so that an invalid value fails at startup instead of at runtime.
+func validateUpgradeGroupBy(groupBy []string) error {
+	seen := make(map[string]struct{}, len(groupBy))
+	for _, l := range groupBy {
+		if l == "..." {
+			if len(groupBy) > 1 {
+				return errors.New("cannot have wildcard group by (`...`) and other labels at the same time")
+			}
+			continue
+		}
+		if !model.LabelName(l).IsValid() {
+			return fmt.Errorf("invalid label name %q", l)
+		}
+		if _, ok := seen[l]; ok {
+			return fmt.Errorf("duplicated label %q", l)
+		}
+		seen[l] = struct{}{}
+	}
+	return nil
+}
+
 func GetAlertmanagerDefaultConfiguration() string {
 	return alertmanagerDefaultConfiguration
 }
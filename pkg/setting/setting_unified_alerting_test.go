@@ -25,6 +25,9 @@ func TestCfg_ReadUnifiedAlertingSettings(t *testing.T) {
 		require.Len(t, cfg.UnifiedAlerting.HAPeers, 0)
 		require.Equal(t, 200*time.Millisecond, cfg.UnifiedAlerting.HAGossipInterval)
 		require.Equal(t, time.Minute, cfg.UnifiedAlerting.HAPushPullInterval)
+		require.Empty(t, cfg.UnifiedAlerting.UpgradeGroupBy)
+		require.False(t, cfg.UnifiedAlerting.UpgradeIgnoreInvalidChannels)
+		require.False(t, cfg.UnifiedAlerting.UpgradeFailBelowMinInterval)
 	}
 
 	// With peers set, it correctly parses them.
@@ -40,6 +43,37 @@ func TestCfg_ReadUnifiedAlertingSettings(t *testing.T) {
 		require.ElementsMatch(t, []string{"hostname1:9090", "hostname2:9090", "hostname3:9090"}, cfg.UnifiedAlerting.HAPeers)
 	}
 
+	// With upgrade group by set, it correctly parses it.
+	{
+		s, err := cfg.Raw.NewSection("unified_alerting")
+		require.NoError(t, err)
+		_, err = s.NewKey("upgrade_group_by", "grafana_folder, alertname,team")
+		require.NoError(t, err)
+
+		require.NoError(t, cfg.ReadUnifiedAlertingSettings(cfg.Raw))
+		require.Equal(t, []string{"grafana_folder", "alertname", "team"}, cfg.UnifiedAlerting.UpgradeGroupBy)
+	}
+
+	t.Run("should reject invalid 'upgrade_group_by'", func(t *testing.T) {
+		s, err := cfg.Raw.NewSection("unified_alerting")
+		require.NoError(t, err)
+		t.Cleanup(func() {
+			s.DeleteKey("upgrade_group_by")
+		})
+
+		for _, groupBy := range []string{"...,alertname", "alertname,...", "alertname,team,alertname", "team-name"} {
+			_, err = s.NewKey("upgrade_group_by", groupBy)
+			require.NoError(t, err)
+
+			require.ErrorContains(t, cfg.ReadUnifiedAlertingSettings(cfg.Raw), "invalid value of setting 'upgrade_group_by'", groupBy)
+		}
+
+		_, err = s.NewKey("upgrade_group_by", "...")
+		require.NoError(t, err)
+		require.NoError(t, cfg.ReadUnifiedAlertingSettings(cfg.Raw))
+		require.Equal(t, []string{"..."}, cfg.UnifiedAlerting.UpgradeGroupBy)
+	})
+
 	t.Run("should read 'scheduler_tick_interval'", func(t *testing.T) {
 		tmp := cfg.IsFeatureToggleEnabled
 		t.Cleanup(func() {